@@ -2,6 +2,7 @@ package providers
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -11,24 +12,78 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	oidc "github.com/coreos/go-oidc"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/sync/singleflight"
 	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/script/v1"
+	fsnotify "gopkg.in/fsnotify.v1"
 )
 
+// googleIssuerURL is the issuer of Google's standard id_tokens; its OIDC
+// discovery document is https://accounts.google.com/.well-known/openid-configuration
+const googleIssuerURL = "https://accounts.google.com"
+
 type GoogleProvider struct {
 	*ProviderData
 	RedeemRefreshURL *url.URL
 	// GroupValidator is a function that determines if the passed email is in
 	// the configured Google group.
 	GroupValidator func(*SessionState) bool
+
+	// oidcVerifier, when set via SetOIDCGroupsClaim, verifies id_tokens
+	// against Google's published JWKS (fetched and refreshed by go-oidc)
+	// instead of relying on the Admin SDK or Apps Script.
+	oidcVerifier      *oidc.IDTokenVerifier
+	oidcGroupsClaim   string
+	oidcAllowedGroups []string
+
+	// hostedDomains, when set via SetHostedDomains, restricts sessions to
+	// id_tokens whose hd claim matches one of the configured domains.
+	hostedDomains []string
+
+	// groupCache memoizes Admin Directory group membership lookups keyed by
+	// "email|group|depth" so RefreshSessionIfNeeded doesn't re-paginate every
+	// configured group on every refresh. groupSF coalesces concurrent
+	// lookups for the same key into a single Admin SDK call.
+	groupCacheMu          sync.RWMutex
+	groupCache            map[string]groupCacheEntry
+	groupCachePositiveTTL time.Duration
+	groupCacheNegativeTTL time.Duration
+	groupSF               singleflight.Group
+
+	// GroupCacheHits, GroupCacheMisses and GroupCacheCoalesced are updated
+	// atomically and may be read by operators to tune the cache TTLs.
+	GroupCacheHits      int64
+	GroupCacheMisses    int64
+	GroupCacheCoalesced int64
+
+	// nestedGroupDepth bounds how many levels of GROUP-type membership are
+	// followed when checking group membership. 0 (the default) preserves
+	// the original direct-membership-only behavior.
+	nestedGroupDepth int
 }
 
+type groupCacheEntry struct {
+	inGroup   bool
+	expiresOn time.Time
+}
+
+const (
+	defaultGroupCachePositiveTTL = 5 * time.Minute
+	defaultGroupCacheNegativeTTL = 30 * time.Second
+)
+
 func NewGoogleProvider(p *ProviderData) *GoogleProvider {
 	p.ProviderName = "Google"
 	if p.LoginURL.String() == "" {
@@ -60,7 +115,153 @@ func NewGoogleProvider(p *ProviderData) *GoogleProvider {
 		GroupValidator: func(*SessionState) bool {
 			return true
 		},
+		groupCachePositiveTTL: defaultGroupCachePositiveTTL,
+		groupCacheNegativeTTL: defaultGroupCacheNegativeTTL,
+	}
+}
+
+// SetGroupCacheTTL overrides the default TTLs used to cache Admin Directory
+// group membership lookups. positive bounds how long a confirmed membership
+// is trusted; negative bounds how long a confirmed non-membership is
+// trusted (kept shorter so newly added members aren't denied for long).
+func (p *GoogleProvider) SetGroupCacheTTL(positive, negative time.Duration) {
+	p.groupCachePositiveTTL = positive
+	p.groupCacheNegativeTTL = negative
+}
+
+// SetNestedGroupDepth configures how many levels of nested GROUP-type
+// membership are followed when checking whether a user belongs to a
+// configured group, e.g. a user in "eng-backend" which is itself a member
+// of "eng-all" is considered a member of "eng-all" when n >= 1. n defaults
+// to 0, which only matches direct USER/CUSTOMER members.
+func (p *GoogleProvider) SetNestedGroupDepth(n int) {
+	p.nestedGroupDepth = n
+}
+
+// SetOIDCGroupsClaim configures the GoogleProvider to treat Google as a
+// standard OIDC provider instead of calling the Admin SDK or Apps Script:
+// id_tokens are verified against Google's published JWKS (discovered from
+// https://accounts.google.com/.well-known/openid-configuration and cached
+// with periodic refresh by go-oidc) and the named claim is read off the
+// verified token as the user's group memberships.
+func (p *GoogleProvider) SetOIDCGroupsClaim(claimName string, allowedGroups []string) error {
+	provider, err := oidc.NewProvider(context.Background(), googleIssuerURL)
+	if err != nil {
+		return fmt.Errorf("could not discover Google OIDC configuration: %v", err)
+	}
+	p.oidcVerifier = provider.Verifier(&oidc.Config{ClientID: p.ClientID})
+	p.oidcGroupsClaim = claimName
+	p.oidcAllowedGroups = allowedGroups
+
+	p.GroupValidator = func(session *SessionState) bool {
+		groups, err := p.groupsFromIDToken(session.IDToken)
+		if err != nil {
+			log.Printf("error validating id_token groups claim: %v", err)
+			return false
+		}
+		session.Groups = groups
+		return len(p.oidcAllowedGroups) == 0 || len(groups) > 0
+	}
+	return nil
+}
+
+// groupsFromIDToken verifies idToken's signature, issuer, audience and
+// expiry against the cached Google JWKS, then returns the intersection of
+// the configured oidcGroupsClaim with oidcAllowedGroups, or every group in
+// the claim when oidcAllowedGroups is empty.
+func (p *GoogleProvider) groupsFromIDToken(idToken string) ([]string, error) {
+	if p.oidcVerifier == nil {
+		return nil, nil
+	}
+	if idToken == "" {
+		return nil, errors.New("missing id_token")
+	}
+	verified, err := p.oidcVerifier.Verify(context.Background(), idToken)
+	if err != nil {
+		return nil, fmt.Errorf("id_token verification failed: %v", err)
+	}
+
+	var claims map[string]interface{}
+	if err := verified.Claims(&claims); err != nil {
+		return nil, err
+	}
+	raw, ok := claims[p.oidcGroupsClaim]
+	if !ok {
+		return []string{}, nil
+	}
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s claim is not a list of strings", p.oidcGroupsClaim)
+	}
+
+	allowed := make(map[string]bool, len(p.oidcAllowedGroups))
+	for _, g := range p.oidcAllowedGroups {
+		allowed[g] = true
+	}
+	ret := []string{}
+	for _, v := range values {
+		group, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if len(allowed) == 0 || allowed[group] {
+			ret = append(ret, group)
+		}
+	}
+	return ret, nil
+}
+
+// SetHostedDomains configures the GoogleProvider to restrict access to
+// sessions whose id_token hd claim matches one of the given GSuite hosted
+// domains. This is checked in Redeem and RefreshSessionIfNeeded before any
+// group validation runs.
+func (p *GoogleProvider) SetHostedDomains(domains []string) {
+	p.hostedDomains = domains
+}
+
+func (p *GoogleProvider) validateHostedDomain(idToken string) error {
+	if len(p.hostedDomains) == 0 {
+		return nil
+	}
+	hd, err := hostedDomainFromIdToken(idToken)
+	if err != nil {
+		return err
 	}
+	for _, allowed := range p.hostedDomains {
+		if hd == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("hosted domain %q is not allowed", hd)
+}
+
+// hostedDomainFromIdToken decodes the hd claim from an id_token without
+// verifying its signature, mirroring the best-effort decode emailFromIdToken
+// already performs.
+func hostedDomainFromIdToken(idToken string) (string, error) {
+	if idToken == "" {
+		return "", errors.New("missing id_token")
+	}
+	jwt := strings.Split(idToken, ".")
+	if len(jwt) < 2 {
+		return "", errors.New("malformed id_token")
+	}
+	jwtData := strings.TrimSuffix(jwt[1], "=")
+	b, err := base64.RawURLEncoding.DecodeString(jwtData)
+	if err != nil {
+		return "", err
+	}
+
+	var claims struct {
+		HostedDomain string `json:"hd"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return "", err
+	}
+	if claims.HostedDomain == "" {
+		return "", errors.New("missing hd claim")
+	}
+	return claims.HostedDomain, nil
 }
 
 func emailFromIdToken(idToken string) (string, error) {
@@ -136,6 +337,9 @@ func (p *GoogleProvider) Redeem(redirectURL, code string) (s *SessionState, err
 	if err != nil {
 		return
 	}
+	if err = p.validateHostedDomain(jsonResponse.IdToken); err != nil {
+		return
+	}
 	var email string
 	email, err = emailFromIdToken(jsonResponse.IdToken)
 	if err != nil {
@@ -146,18 +350,139 @@ func (p *GoogleProvider) Redeem(redirectURL, code string) (s *SessionState, err
 		ExpiresOn:    time.Now().Add(time.Duration(jsonResponse.ExpiresIn) * time.Second).Truncate(time.Second),
 		RefreshToken: jsonResponse.RefreshToken,
 		Email:        email,
+		IDToken:      jsonResponse.IdToken,
+	}
+	if p.oidcVerifier != nil {
+		if s.Groups, err = p.groupsFromIDToken(s.IDToken); err != nil {
+			return
+		}
+		if len(p.oidcAllowedGroups) > 0 && len(s.Groups) == 0 {
+			err = fmt.Errorf("%s is not in any of the allowed groups", email)
+			return
+		}
 	}
 	return
 }
 
+var adminDirectoryScopes = []string{admin.AdminDirectoryUserReadonlyScope, admin.AdminDirectoryGroupReadonlyScope}
+
 // SetGroupRestriction configures the GoogleProvider to restrict access to the
 // specified group(s). AdminEmail has to be an administrative email on the domain that is
 // checked. CredentialsFile is the path to a json file containing a Google service
 // account credentials.
-func (p *GoogleProvider) SetGroupRestriction(groups []string, adminEmail string, credentialsReader io.Reader) {
-	adminService := getAdminService(adminEmail, credentialsReader)
+func (p *GoogleProvider) SetGroupRestriction(groups []string, adminEmail string, credentialsReader io.Reader) error {
+	adminService, err := getAdminService(adminEmail, credentialsReader)
+	if err != nil {
+		return err
+	}
+	p.setGroupValidator(adminService, groups)
+	return nil
+}
+
+// SetGroupRestrictionFromFile behaves like SetGroupRestriction but reads the
+// service account credentials from a file path and keeps watching for
+// rotation, re-loading the Admin SDK client whenever the credentials change
+// on disk.
+func (p *GoogleProvider) SetGroupRestrictionFromFile(groups []string, adminEmail string, credentialsFile string) error {
+	if err := p.loadGroupRestrictionFromFile(groups, adminEmail, credentialsFile); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("can't watch %s for rotation: %v", credentialsFile, err)
+	}
+	// Secret-delivery mechanisms (k8s secret mounts, etc.) rotate
+	// credentials by atomically renaming a new file into place rather than
+	// rewriting the existing inode, so inotify has to watch the containing
+	// directory -- a watch on credentialsFile itself stops seeing events
+	// once the original inode is replaced.
+	dir := filepath.Dir(credentialsFile)
+	base := filepath.Base(credentialsFile)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("can't watch %s for rotation: %v", dir, err)
+	}
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != base {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if err := p.loadGroupRestrictionFromFile(groups, adminEmail, credentialsFile); err != nil {
+					log.Printf("error reloading rotated Google credentials from %s: %v", credentialsFile, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("error watching %s for rotation: %v", credentialsFile, err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *GoogleProvider) loadGroupRestrictionFromFile(groups []string, adminEmail string, credentialsFile string) error {
+	f, err := os.Open(credentialsFile)
+	if err != nil {
+		return fmt.Errorf("can't open Google credentials file: %v", err)
+	}
+	defer f.Close()
+
+	adminService, err := getAdminService(adminEmail, f)
+	if err != nil {
+		return err
+	}
+	p.setGroupValidator(adminService, groups)
+	return nil
+}
+
+// SetGroupRestrictionFromADC behaves like SetGroupRestriction but discovers
+// Application Default Credentials instead of requiring a service account
+// JSON file to be mounted explicitly. In practice this only supports
+// GOOGLE_APPLICATION_CREDENTIALS-style ADC: the Admin Directory API requires
+// domain-wide delegation, which means impersonating adminEmail with a
+// private key the same way getAdminService does for the file-based path,
+// and ADC sources without a service account key -- GCE/GKE metadata-server
+// workload identity chief among them -- have no key to impersonate with.
+// Those are rejected with an error rather than silently failing; use
+// SetGroupRestriction or SetGroupRestrictionFromFile if the credentials only
+// exist as a mounted/rotated JSON file.
+func (p *GoogleProvider) SetGroupRestrictionFromADC(groups []string, adminEmail string) error {
+	creds, err := google.FindDefaultCredentials(context.Background(), adminDirectoryScopes...)
+	if err != nil {
+		return fmt.Errorf("can't load Google application default credentials: %v", err)
+	}
+	if len(creds.JSON) == 0 {
+		return fmt.Errorf("application default credentials have no service account key to impersonate %s with; mount a key via GOOGLE_APPLICATION_CREDENTIALS or use SetGroupRestriction", adminEmail)
+	}
+	conf, err := google.JWTConfigFromJSON(creds.JSON, adminDirectoryScopes...)
+	if err != nil {
+		return fmt.Errorf("can't parse application default credentials: %v", err)
+	}
+	conf.Subject = adminEmail
+
+	client := conf.Client(context.Background())
+	adminService, err := admin.New(client)
+	if err != nil {
+		return err
+	}
+	p.setGroupValidator(adminService, groups)
+	return nil
+}
+
+func (p *GoogleProvider) setGroupValidator(adminService *admin.Service, groups []string) {
 	p.GroupValidator = func(session *SessionState) bool {
-		userGroups := userInGroup(adminService, groups, session.Email)
+		userGroups := p.groupsForEmail(adminService, groups, session.Email)
 		if len(userGroups) > 0 {
 			session.Groups = userGroups
 			return true
@@ -166,62 +491,152 @@ func (p *GoogleProvider) SetGroupRestriction(groups []string, adminEmail string,
 	}
 }
 
-func getAdminService(adminEmail string, credentialsReader io.Reader) *admin.Service {
+func getAdminService(adminEmail string, credentialsReader io.Reader) (*admin.Service, error) {
 	data, err := ioutil.ReadAll(credentialsReader)
 	if err != nil {
-		log.Fatal("can't read Google credentials file:", err)
+		return nil, fmt.Errorf("can't read Google credentials file: %v", err)
 	}
-	conf, err := google.JWTConfigFromJSON(data, admin.AdminDirectoryUserReadonlyScope, admin.AdminDirectoryGroupReadonlyScope)
+	conf, err := google.JWTConfigFromJSON(data, adminDirectoryScopes...)
 	if err != nil {
-		log.Fatal("can't load Google credentials file:", err)
+		return nil, fmt.Errorf("can't load Google credentials file: %v", err)
 	}
 	conf.Subject = adminEmail
 
 	client := conf.Client(oauth2.NoContext)
 	adminService, err := admin.New(client)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return adminService
+	return adminService, nil
 }
 
-func userInGroup(service *admin.Service, groups []string, email string) (ret []string) {
+// groupsForEmail returns the subset of groups that email belongs to,
+// consulting the group-membership cache first and coalescing concurrent
+// cache misses for the same (email, group) via singleflight.
+func (p *GoogleProvider) groupsForEmail(service *admin.Service, groups []string, email string) (ret []string) {
 	ret = []string{}
+	for _, group := range groups {
+		inGroup, err := p.cachedUserInGroup(service, group, email)
+		if err != nil {
+			log.Printf("error checking group membership for %s in %s: %v", email, group, err)
+			continue
+		}
+		if inGroup {
+			ret = append(ret, group)
+		}
+	}
+	return
+}
+
+func (p *GoogleProvider) cachedUserInGroup(service *admin.Service, group, email string) (bool, error) {
+	return p.cachedUserInGroupDepth(service, group, email, p.nestedGroupDepth, map[string]bool{})
+}
+
+// cachedUserInGroupDepth is cachedUserInGroup plus the recursion state
+// (remaining depth and the set of groups already visited on this chain, to
+// guard against cycles) threaded through nested GROUP-type expansion.
+func (p *GoogleProvider) cachedUserInGroupDepth(service *admin.Service, group, email string, depth int, visited map[string]bool) (bool, error) {
+	if visited[group] {
+		return false, nil
+	}
+	// visited is shared across the whole top-level cachedUserInGroup call,
+	// so the mark has to be path-relative: remove it once this branch is
+	// done rather than leaving it set for the rest of the traversal, or a
+	// sibling branch reaching the same group via a shorter path would be
+	// short-circuited by a visit that's no longer an ancestor of it.
+	visited[group] = true
+	defer delete(visited, group)
+
+	// depth is part of the key: a group reached with fewer remaining hops
+	// explores strictly less of its own nested membership than the same
+	// group reached with more remaining hops, so the two results aren't
+	// interchangeable even though the (email, group) pair matches.
+	key := email + "|" + group + "|" + strconv.Itoa(depth)
+	if inGroup, ok := p.lookupGroupCache(key); ok {
+		atomic.AddInt64(&p.GroupCacheHits, 1)
+		return inGroup, nil
+	}
+	atomic.AddInt64(&p.GroupCacheMisses, 1)
+
+	v, err, shared := p.groupSF.Do(key, func() (interface{}, error) {
+		return p.userInGroup(service, group, email, depth, visited)
+	})
+	if shared {
+		atomic.AddInt64(&p.GroupCacheCoalesced, 1)
+	}
+	if err != nil {
+		return false, err
+	}
+	inGroup := v.(bool)
+	p.storeGroupCache(key, inGroup)
+	return inGroup, nil
+}
+
+func (p *GoogleProvider) lookupGroupCache(key string) (inGroup bool, ok bool) {
+	p.groupCacheMu.RLock()
+	defer p.groupCacheMu.RUnlock()
+	entry, found := p.groupCache[key]
+	if !found || time.Now().After(entry.expiresOn) {
+		return false, false
+	}
+	return entry.inGroup, true
+}
+
+func (p *GoogleProvider) storeGroupCache(key string, inGroup bool) {
+	ttl := p.groupCachePositiveTTL
+	if !inGroup {
+		ttl = p.groupCacheNegativeTTL
+	}
+	p.groupCacheMu.Lock()
+	defer p.groupCacheMu.Unlock()
+	if p.groupCache == nil {
+		p.groupCache = make(map[string]groupCacheEntry)
+	}
+	p.groupCache[key] = groupCacheEntry{inGroup: inGroup, expiresOn: time.Now().Add(ttl)}
+}
+
+// userInGroup reports whether email is a USER or CUSTOMER member of group,
+// or, while depth > 0, a member of a GROUP-type member of group (and so on,
+// recursively, up to depth levels deep).
+func (p *GoogleProvider) userInGroup(service *admin.Service, group string, email string, depth int, visited map[string]bool) (bool, error) {
 	user, err := fetchUser(service, email)
 	if err != nil {
-		log.Printf("error fetching user: %v", err)
-		return
+		return false, fmt.Errorf("error fetching user: %v", err)
 	}
-	id := user.Id
-	custID := user.CustomerId
 
-	for _, group := range groups {
-		members, err := fetchGroupMembers(service, group)
-		if err != nil {
-			if err, ok := err.(*googleapi.Error); ok && err.Code == 404 {
-				log.Printf("error fetching members for group %s: group does not exist", group)
-			} else {
-				log.Printf("error fetching group members: %v", err)
-				return
-			}
+	members, err := fetchGroupMembers(service, group)
+	if err != nil {
+		if err, ok := err.(*googleapi.Error); ok && err.Code == 404 {
+			log.Printf("error fetching members for group %s: group does not exist", group)
+			return false, nil
 		}
-	membersearch:
-		for _, member := range members {
-			switch member.Type {
-			case "CUSTOMER":
-				if member.Id == custID {
-					ret = append(ret, group)
-					break membersearch
-				}
-			case "USER":
-				if member.Id == id {
-					ret = append(ret, group)
-					break membersearch
-				}
+		return false, fmt.Errorf("error fetching group members: %v", err)
+	}
+	for _, member := range members {
+		switch member.Type {
+		case "CUSTOMER":
+			if member.Id == user.CustomerId {
+				return true, nil
+			}
+		case "USER":
+			if member.Id == user.Id {
+				return true, nil
+			}
+		case "GROUP":
+			if depth <= 0 {
+				continue
+			}
+			inNested, err := p.cachedUserInGroupDepth(service, member.Id, email, depth-1, visited)
+			if err != nil {
+				log.Printf("error checking nested group %s: %v", member.Id, err)
+				continue
+			}
+			if inNested {
+				return true, nil
 			}
 		}
 	}
-	return
+	return false, nil
 }
 
 func fetchUser(service *admin.Service, email string) (*admin.User, error) {
@@ -263,10 +678,31 @@ func (p *GoogleProvider) RefreshSessionIfNeeded(s *SessionState) (bool, error) {
 		return false, nil
 	}
 
-	newToken, duration, err := p.redeemRefreshToken(s.RefreshToken)
+	newToken, newIDToken, duration, err := p.redeemRefreshToken(s.RefreshToken)
 	if err != nil {
 		return false, err
 	}
+	// Google only returns a new id_token on a refresh grant if the
+	// original authorization request asked for the openid scope; fall
+	// back to the previous one rather than re-validating a blank token.
+	if newIDToken != "" {
+		s.IDToken = newIDToken
+	}
+
+	if err := p.validateHostedDomain(s.IDToken); err != nil {
+		return false, fmt.Errorf("hosted domain re-validation failed for %s: %v", s.Email, err)
+	}
+
+	if p.oidcVerifier != nil {
+		groups, err := p.groupsFromIDToken(s.IDToken)
+		if err != nil {
+			return false, fmt.Errorf("id_token re-validation failed for %s: %v", s.Email, err)
+		}
+		if len(p.oidcAllowedGroups) > 0 && len(groups) == 0 {
+			return false, fmt.Errorf("%s is no longer in the group(s)", s.Email)
+		}
+		s.Groups = groups
+	}
 
 	// re-check that the user is in the proper google group(s)
 	if !p.ValidateGroup(s) {
@@ -280,7 +716,7 @@ func (p *GoogleProvider) RefreshSessionIfNeeded(s *SessionState) (bool, error) {
 	return true, nil
 }
 
-func (p *GoogleProvider) redeemRefreshToken(refreshToken string) (token string, expires time.Duration, err error) {
+func (p *GoogleProvider) redeemRefreshToken(refreshToken string) (token string, idToken string, expires time.Duration, err error) {
 	// https://developers.google.com/identity/protocols/OAuth2WebServer#refresh
 	params := url.Values{}
 	params.Add("client_id", p.ClientID)
@@ -312,6 +748,7 @@ func (p *GoogleProvider) redeemRefreshToken(refreshToken string) (token string,
 
 	var data struct {
 		AccessToken string `json:"access_token"`
+		IdToken     string `json:"id_token"`
 		ExpiresIn   int64  `json:"expires_in"`
 	}
 	err = json.Unmarshal(body, &data)
@@ -319,6 +756,7 @@ func (p *GoogleProvider) redeemRefreshToken(refreshToken string) (token string,
 		return
 	}
 	token = data.AccessToken
+	idToken = data.IdToken
 	expires = time.Duration(data.ExpiresIn) * time.Second
 	return
 }
@@ -0,0 +1,325 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	oidc "github.com/coreos/go-oidc"
+	admin "google.golang.org/api/admin/directory/v1"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// fakeIdToken base64url-encodes claims into the payload segment of a
+// two-dot JWT shape, without signing it -- sufficient for exercising the
+// unverified best-effort decoders (hostedDomainFromIdToken, emailFromIdToken).
+func fakeIdToken(t *testing.T, claims interface{}) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	return "header." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestHostedDomainFromIdToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		idToken string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "empty id_token",
+			idToken: "",
+			wantErr: true,
+		},
+		{
+			name:    "malformed id_token",
+			idToken: "not-a-jwt",
+			wantErr: true,
+		},
+		{
+			name:    "missing hd claim",
+			idToken: fakeIdToken(t, struct{}{}),
+			wantErr: true,
+		},
+		{
+			name: "valid hd claim",
+			idToken: fakeIdToken(t, struct {
+				Hd string `json:"hd"`
+			}{Hd: "example.com"}),
+			want: "example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hostedDomainFromIdToken(tt.idToken)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("hostedDomainFromIdToken(%q) error = %v, wantErr %v", tt.idToken, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("hostedDomainFromIdToken(%q) = %q, want %q", tt.idToken, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedeemRefreshTokenCapturesIdToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access-token","id_token":"new-id-token","expires_in":3600}`))
+	}))
+	defer ts.Close()
+
+	redeemURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+	p := &GoogleProvider{ProviderData: &ProviderData{RedeemURL: redeemURL}}
+
+	token, idToken, expires, err := p.redeemRefreshToken("refresh-token")
+	if err != nil {
+		t.Fatalf("redeemRefreshToken returned error: %v", err)
+	}
+	if token != "new-access-token" {
+		t.Errorf("token = %q, want %q", token, "new-access-token")
+	}
+	if idToken != "new-id-token" {
+		t.Errorf("idToken = %q, want %q", idToken, "new-id-token")
+	}
+	if expires.Seconds() != 3600 {
+		t.Errorf("expires = %v, want 3600s", expires)
+	}
+}
+
+// newFakeAdminService spins up an httptest server that answers Admin
+// Directory Users.Get/Members.List calls from a fixed in-memory directory
+// and returns an *admin.Service pointed at it.
+func newFakeAdminService(t *testing.T, users map[string]*admin.User, groupMembers map[string][]*admin.Member) (*admin.Service, func()) {
+	t.Helper()
+	mux := http.NewServeMux()
+	for key, user := range users {
+		u := user
+		mux.HandleFunc("/users/"+key, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(u)
+		})
+	}
+	for group, members := range groupMembers {
+		m := members
+		mux.HandleFunc("/groups/"+group+"/members", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(&admin.Members{Members: m})
+		})
+	}
+	ts := httptest.NewServer(mux)
+
+	service, err := admin.New(ts.Client())
+	if err != nil {
+		ts.Close()
+		t.Fatalf("admin.New: %v", err)
+	}
+	service.BasePath = ts.URL + "/"
+	return service, ts.Close
+}
+
+// TestNestedGroupCacheRespectsDepth reproduces a user reaching the same
+// nested group N through two different top-level groups at two different
+// remaining depths: A -> N (1 hop) and C -> M -> N (2 hops). With
+// SetNestedGroupDepth(2), expanding via A still has one hop of budget left
+// at N (so N's own nested member Z, of which the user is a direct member,
+// is reachable) while expanding via C has none left at N (so Z must not be
+// explored). The two results must not collide in the membership cache.
+func TestNestedGroupCacheRespectsDepth(t *testing.T) {
+	const email = "alice@example.com"
+	users := map[string]*admin.User{
+		email: {Id: "u1", CustomerId: "c1"},
+	}
+	groupMembers := map[string][]*admin.Member{
+		"A": {{Id: "N", Type: "GROUP"}},
+		"C": {{Id: "M", Type: "GROUP"}},
+		"M": {{Id: "N", Type: "GROUP"}},
+		"N": {{Id: "Z", Type: "GROUP"}},
+		"Z": {{Id: "u1", Type: "USER"}},
+	}
+	service, closeFn := newFakeAdminService(t, users, groupMembers)
+	defer closeFn()
+
+	p := &GoogleProvider{ProviderData: &ProviderData{}}
+	p.groupCachePositiveTTL = defaultGroupCachePositiveTTL
+	p.groupCacheNegativeTTL = defaultGroupCacheNegativeTTL
+	p.SetNestedGroupDepth(2)
+
+	inA, err := p.cachedUserInGroup(service, "A", email)
+	if err != nil {
+		t.Fatalf("cachedUserInGroup(A): %v", err)
+	}
+	if !inA {
+		t.Errorf("expected %s to be in A via A->N->Z, got false", email)
+	}
+
+	inC, err := p.cachedUserInGroup(service, "C", email)
+	if err != nil {
+		t.Fatalf("cachedUserInGroup(C): %v", err)
+	}
+	if inC {
+		t.Errorf("expected %s NOT to be in C: C->M->N exhausts the depth budget before reaching Z, so a cache hit borrowed from the A->N traversal would wrongly report membership", email)
+	}
+}
+
+// TestNestedGroupVisitedIsPathRelative reproduces a user reaching the same
+// nested group N twice within a single cachedUserInGroup(G) call: once via
+// G->A->N (which exhausts the depth budget before N's own members) and once
+// via G->N directly (with depth to spare). The direct visit must not be
+// short-circuited by the earlier, now-finished visit to N from the A
+// branch -- visited has to track the current path, not the whole traversal.
+func TestNestedGroupVisitedIsPathRelative(t *testing.T) {
+	const email = "alice@example.com"
+	users := map[string]*admin.User{
+		email: {Id: "u1", CustomerId: "c1"},
+	}
+	groupMembers := map[string][]*admin.Member{
+		"G": {{Id: "A", Type: "GROUP"}, {Id: "N", Type: "GROUP"}},
+		"A": {{Id: "N", Type: "GROUP"}},
+		"N": {{Id: "M", Type: "GROUP"}},
+		"M": {{Id: "u1", Type: "USER"}},
+	}
+	service, closeFn := newFakeAdminService(t, users, groupMembers)
+	defer closeFn()
+
+	p := &GoogleProvider{ProviderData: &ProviderData{}}
+	p.groupCachePositiveTTL = defaultGroupCachePositiveTTL
+	p.groupCacheNegativeTTL = defaultGroupCacheNegativeTTL
+	p.SetNestedGroupDepth(2)
+
+	inG, err := p.cachedUserInGroup(service, "G", email)
+	if err != nil {
+		t.Fatalf("cachedUserInGroup(G): %v", err)
+	}
+	if !inG {
+		t.Errorf("expected %s to be in G via G->N->M, got false", email)
+	}
+}
+
+// staticKeySet is a minimal oidc.KeySet backed by a single known JWK, so
+// tests can verify signed tokens without standing up a JWKS endpoint.
+type staticKeySet struct {
+	jwk jose.JSONWebKey
+}
+
+func (s *staticKeySet) VerifySignature(ctx context.Context, token string) ([]byte, error) {
+	jws, err := jose.ParseSigned(token)
+	if err != nil {
+		return nil, err
+	}
+	return jws.Verify(&s.jwk)
+}
+
+func signClaims(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	compact, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+	return compact
+}
+
+func newTestOIDCProvider(t *testing.T, key *rsa.PrivateKey, issuer, clientID string) *GoogleProvider {
+	t.Helper()
+	keySet := &staticKeySet{jwk: jose.JSONWebKey{Key: &key.PublicKey, Algorithm: "RS256", Use: "sig"}}
+	p := &GoogleProvider{ProviderData: &ProviderData{ClientID: clientID}}
+	p.oidcVerifier = oidc.NewVerifier(issuer, keySet, &oidc.Config{ClientID: clientID})
+	return p
+}
+
+func TestGroupsFromIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	const issuer = "https://example-issuer.test"
+	const clientID = "test-client"
+
+	t.Run("no verifier configured is a no-op", func(t *testing.T) {
+		p := &GoogleProvider{ProviderData: &ProviderData{}}
+		groups, err := p.groupsFromIDToken("irrelevant")
+		if err != nil || groups != nil {
+			t.Errorf("groupsFromIDToken() = %v, %v, want nil, nil", groups, err)
+		}
+	})
+
+	t.Run("filters to allowed groups", func(t *testing.T) {
+		p := newTestOIDCProvider(t, key, issuer, clientID)
+		p.oidcGroupsClaim = "groups"
+		p.oidcAllowedGroups = []string{"eng", "ops"}
+		idToken := signClaims(t, key, map[string]interface{}{
+			"iss":    issuer,
+			"aud":    clientID,
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"groups": []string{"eng", "some-other-group"},
+		})
+		groups, err := p.groupsFromIDToken(idToken)
+		if err != nil {
+			t.Fatalf("groupsFromIDToken: %v", err)
+		}
+		if len(groups) != 1 || groups[0] != "eng" {
+			t.Errorf("groups = %v, want [eng]", groups)
+		}
+	})
+
+	t.Run("missing groups claim returns empty", func(t *testing.T) {
+		p := newTestOIDCProvider(t, key, issuer, clientID)
+		p.oidcGroupsClaim = "groups"
+		idToken := signClaims(t, key, map[string]interface{}{
+			"iss": issuer,
+			"aud": clientID,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		groups, err := p.groupsFromIDToken(idToken)
+		if err != nil {
+			t.Fatalf("groupsFromIDToken: %v", err)
+		}
+		if len(groups) != 0 {
+			t.Errorf("groups = %v, want empty", groups)
+		}
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		p := newTestOIDCProvider(t, key, issuer, clientID)
+		p.oidcGroupsClaim = "groups"
+		idToken := signClaims(t, key, map[string]interface{}{
+			"iss":    issuer,
+			"aud":    clientID,
+			"exp":    time.Now().Add(-time.Hour).Unix(),
+			"groups": []string{"eng"},
+		})
+		if _, err := p.groupsFromIDToken(idToken); err == nil {
+			t.Error("expected an error for an expired id_token, got nil")
+		}
+	})
+
+	t.Run("empty id_token is rejected", func(t *testing.T) {
+		p := newTestOIDCProvider(t, key, issuer, clientID)
+		if _, err := p.groupsFromIDToken(""); err == nil {
+			t.Error("expected an error for an empty id_token, got nil")
+		}
+	})
+}